@@ -0,0 +1,75 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+// CELInterceptor provides a webhook to intercept and pre-process events
+type CELInterceptor struct {
+	// Filter is a CEL expression that, if it does not evaluate to true,
+	// will abort processing of the event.
+	Filter string `json:"filter,omitempty"`
+	// Overlays are CEL expressions that are applied to the incoming event
+	// payload, in order, before it is passed on to the TriggerBindings.
+	Overlays []CELOverlay `json:"overlays,omitempty"`
+	// SecretRefs allowlists the Kubernetes Secrets (and optionally specific
+	// keys within them) that the `secrets` variable and `secretRef` function
+	// may read in Filter and Overlays expressions. A filter referencing a
+	// Secret or key not listed here is rejected rather than silently
+	// returning empty, so that a filter cannot be used to exfiltrate
+	// arbitrary cluster secrets.
+	SecretRefs []SecretRef `json:"secretRefs,omitempty"`
+}
+
+// SecretRef identifies a Kubernetes Secret, and optionally a single key
+// within it, that a CEL expression is permitted to read.
+type SecretRef struct {
+	// SecretName is the name of the Secret, in the EventListener's
+	// namespace.
+	SecretName string `json:"secretName"`
+	// SecretKey restricts access to a single key within the Secret. If
+	// empty, every key in the Secret is allowed.
+	SecretKey string `json:"secretKey,omitempty"`
+}
+
+// CELOverlayKind determines how an overlay's expression result is merged
+// into the event payload.
+type CELOverlayKind string
+
+const (
+	// CELOverlayKindSJSON applies the overlay expression result as a raw
+	// value at Key using sjson's dotted-path syntax. This is the default.
+	CELOverlayKindSJSON CELOverlayKind = "sjson"
+	// CELOverlayKindJSONPatch treats the overlay expression result as a
+	// list of RFC 6902 JSON Patch operations to apply to the payload. Key
+	// is ignored for this kind.
+	CELOverlayKindJSONPatch CELOverlayKind = "jsonpatch"
+	// CELOverlayKindJSONMerge treats the overlay expression result as an
+	// RFC 7396 JSON Merge Patch document to apply to the payload. Key is
+	// ignored for this kind.
+	CELOverlayKindJSONMerge CELOverlayKind = "jsonmerge"
+)
+
+// CELOverlay provides a way to modify the given event payload using CEL expressions
+type CELOverlay struct {
+	// Kind selects how Expression's result is merged into the payload.
+	// Defaults to CELOverlayKindSJSON.
+	Kind CELOverlayKind `json:"kind,omitempty"`
+	// Key is the sjson path the overlay result is written to. Only used
+	// when Kind is CELOverlayKindSJSON.
+	Key string `json:"key,omitempty"`
+	// Expression is the CEL expression to evaluate.
+	Expression string `json:"expression,omitempty"`
+}
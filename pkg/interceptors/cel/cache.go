@@ -0,0 +1,104 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cel
+
+import (
+	"container/list"
+	"fmt"
+	"sync"
+
+	"github.com/google/cel-go/cel"
+)
+
+// maxCheckedExprCacheSize bounds checkedExprCache so that an EventListener
+// configured with many distinct Triggers (and therefore many distinct
+// expressions) cannot grow the cache without bound.
+const maxCheckedExprCacheSize = 1024
+
+// checkedExprCache is the package-level cache of parsed-and-type-checked CEL
+// expressions, shared by every Interceptor. See compiledProgram.
+var checkedExprCache = newExprCache(maxCheckedExprCacheSize)
+
+type exprCacheKey struct {
+	expr        string
+	fingerprint string
+}
+
+// exprCache is a bounded, concurrency-safe LRU cache from (expression text,
+// env fingerprint) to a parsed-and-checked *cel.Ast.
+type exprCache struct {
+	mu       sync.Mutex
+	capacity int
+	ll       *list.List
+	items    map[exprCacheKey]*list.Element
+}
+
+type exprCacheEntry struct {
+	key     exprCacheKey
+	checked *cel.Ast
+}
+
+func newExprCache(capacity int) *exprCache {
+	return &exprCache{
+		capacity: capacity,
+		ll:       list.New(),
+		items:    make(map[exprCacheKey]*list.Element, capacity),
+	}
+}
+
+// get returns the checked AST for expr against env, parsing and
+// type-checking it (and storing the result) on a cache miss.
+func (c *exprCache) get(env *cel.Env, expr string) (*cel.Ast, error) {
+	key := exprCacheKey{expr: expr, fingerprint: envFingerprint}
+
+	c.mu.Lock()
+	if el, ok := c.items[key]; ok {
+		c.ll.MoveToFront(el)
+		checked := el.Value.(*exprCacheEntry).checked
+		c.mu.Unlock()
+		return checked, nil
+	}
+	c.mu.Unlock()
+
+	parsed, issues := env.Parse(expr)
+	if issues != nil && issues.Err() != nil {
+		return nil, fmt.Errorf("failed to parse expression %#v: %s", expr, issues.Err())
+	}
+
+	checked, issues := env.Check(parsed)
+	if issues != nil && issues.Err() != nil {
+		return nil, fmt.Errorf("expression %#v check failed: %s", expr, issues.Err())
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.items[key]; ok {
+		c.ll.MoveToFront(el)
+		return el.Value.(*exprCacheEntry).checked, nil
+	}
+	el := c.ll.PushFront(&exprCacheEntry{key: key, checked: checked})
+	c.items[key] = el
+	for c.ll.Len() > c.capacity {
+		oldest := c.ll.Back()
+		if oldest == nil {
+			break
+		}
+		c.ll.Remove(oldest)
+		delete(c.items, oldest.Value.(*exprCacheEntry).key)
+	}
+	return checked, nil
+}
@@ -18,12 +18,18 @@ package cel
 
 import (
 	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"net/http"
 	"reflect"
+	"sync"
+	"time"
 
+	"github.com/evanphx/json-patch"
 	structpb "github.com/golang/protobuf/ptypes/struct"
 	"github.com/google/cel-go/cel"
 	"github.com/google/cel-go/checker/decls"
@@ -33,6 +39,11 @@ import (
 	celext "github.com/google/cel-go/ext"
 	"github.com/tektoncd/triggers/pkg/interceptors"
 	"github.com/tidwall/sjson"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 	"google.golang.org/protobuf/encoding/protojson"
 	"google.golang.org/protobuf/proto"
@@ -41,6 +52,16 @@ import (
 	triggersv1 "github.com/tektoncd/triggers/pkg/apis/triggers/v1alpha1"
 )
 
+// tracer emits spans for CEL interceptor execution. The instrumentation
+// name matches the package's import path, per OpenTelemetry convention.
+var tracer = otel.Tracer("github.com/tektoncd/triggers/pkg/interceptors/cel")
+
+// maxTracedExprLen bounds how much of an expression's text is attached to a
+// span verbatim. Longer expressions are recorded as a hash instead, so a
+// span can't balloon in size (or leak an unexpectedly sensitive literal)
+// just because a filter is long.
+const maxTracedExprLen = 256
+
 // Interceptor implements a CEL based interceptor that uses CEL expressions
 // against the incoming body and headers to match, if the expression returns
 // a true value, then the interception is "successful".
@@ -49,6 +70,10 @@ type Interceptor struct {
 	Logger                 *zap.SugaredLogger
 	CEL                    *triggersv1.CELInterceptor
 	EventListenerNamespace string
+
+	envOnce sync.Once
+	env     *cel.Env
+	envErr  error
 }
 
 var (
@@ -69,40 +94,55 @@ func NewInterceptor(cel *triggersv1.CELInterceptor, k kubernetes.Interface, ns s
 
 // ExecuteTrigger is an implementation of the Interceptor interface.
 func (w *Interceptor) ExecuteTrigger(request *http.Request) (*http.Response, error) {
-	env, err := makeCelEnv(request, w.EventListenerNamespace, w.KubeClientSet)
-	if err != nil {
-		return nil, fmt.Errorf("error creating cel environment: %w", err)
-	}
+	ctx := otel.GetTextMapPropagator().Extract(request.Context(), propagation.HeaderCarrier(request.Header))
+	ctx, span := tracer.Start(ctx, "cel.ExecuteTrigger")
+	defer span.End()
 
 	var payload = []byte(`{}`)
+	var err error
 	if request.Body != nil {
 		defer request.Body.Close()
 		payload, err = ioutil.ReadAll(request.Body)
 		if err != nil {
-			return nil, fmt.Errorf("error reading request body: %w", err)
+			return nil, traceErr(span, fmt.Errorf("error reading request body: %w", err))
 		}
 	}
 
-	evalContext, err := makeEvalContext(payload, request)
+	env, err := w.getEnv()
 	if err != nil {
-		return nil, fmt.Errorf("error making the evaluation context: %w", err)
+		return nil, traceErr(span, fmt.Errorf("error creating cel environment: %w", err))
 	}
 
+	evalContext, err := makeEvalContext(payload, request, w.EventListenerNamespace, w.KubeClientSet, w.CEL)
+	if err != nil {
+		return nil, traceErr(span, fmt.Errorf("error making the evaluation context: %w", err))
+	}
+
+	// requestScopedFns binds verifyGitHubSignature/verifyBitbucketSignature
+	// against this request's raw body. The env itself (declarations) is
+	// built once per Interceptor and cached, but these two functions need
+	// the current request's raw bytes, so they're bound fresh per request
+	// as an extra cel.ProgramOption at Program() time, not baked into the
+	// cached env — this keeps the CEL-facing function signature at the
+	// documented 2 arguments (secretRef, header) instead of leaking an
+	// implementation detail into the expression language.
+	requestScopedFns := rawBodyFunctions(w.KubeClientSet, w.EventListenerNamespace, payload)
+
 	if w.CEL.Filter != "" {
-		out, err := evaluate(w.CEL.Filter, env, evalContext)
+		out, err := evaluate(ctx, w.CEL.Filter, env, evalContext, requestScopedFns)
 		if err != nil {
-			return nil, err
+			return nil, traceErr(span, err)
 		}
 
 		if out != types.True {
-			return nil, fmt.Errorf("expression %s did not return true", w.CEL.Filter)
+			return nil, traceErr(span, fmt.Errorf("expression %s did not return true", w.CEL.Filter))
 		}
 	}
 
 	for _, u := range w.CEL.Overlays {
-		val, err := evaluate(u.Expression, env, evalContext)
+		val, err := evaluate(ctx, u.Expression, env, evalContext, requestScopedFns)
 		if err != nil {
-			return nil, err
+			return nil, traceErr(span, err)
 		}
 
 		var raw interface{}
@@ -148,12 +188,12 @@ func (w *Interceptor) ExecuteTrigger(request *http.Request) (*http.Response, err
 		}
 
 		if err != nil {
-			return nil, fmt.Errorf("failed to convert overlay result to bytes: %w", err)
+			return nil, traceErr(span, fmt.Errorf("failed to convert overlay result to bytes: %w", err))
 		}
 
-		payload, err = sjson.SetRawBytes(payload, u.Key, b)
+		payload, err = applyOverlay(ctx, payload, u, b)
 		if err != nil {
-			return nil, fmt.Errorf("failed to sjson for key '%s' to '%s': %w", u.Key, val, err)
+			return nil, traceErr(span, err)
 		}
 	}
 
@@ -164,33 +204,137 @@ func (w *Interceptor) ExecuteTrigger(request *http.Request) (*http.Response, err
 
 }
 
-func evaluate(expr string, env *cel.Env, data map[string]interface{}) (ref.Val, error) {
-	parsed, issues := env.Parse(expr)
-	if issues != nil && issues.Err() != nil {
-		return nil, fmt.Errorf("failed to parse expression %#v: %s", expr, issues.Err())
+// applyOverlay merges an overlay expression's result (already converted to
+// raw JSON bytes in b) into payload, according to the overlay's Kind.
+func applyOverlay(ctx context.Context, payload []byte, u triggersv1.CELOverlay, b []byte) ([]byte, error) {
+	kind := u.Kind
+	if kind == "" {
+		kind = triggersv1.CELOverlayKindSJSON
 	}
+	_, span := tracer.Start(ctx, "cel.applyOverlay", trace.WithAttributes(
+		attribute.String("triggers.cel.overlay.kind", string(kind)),
+		attribute.String("triggers.cel.overlay.key", u.Key),
+	))
+	defer span.End()
 
-	checked, issues := env.Check(parsed)
-	if issues != nil && issues.Err() != nil {
-		return nil, fmt.Errorf("expression %#v check failed: %s", expr, issues.Err())
+	var out []byte
+	var err error
+	switch u.Kind {
+	case triggersv1.CELOverlayKindJSONPatch:
+		var patch jsonpatch.Patch
+		patch, err = jsonpatch.DecodePatch(b)
+		if err != nil {
+			err = fmt.Errorf("failed to decode jsonpatch overlay result %q: %w", b, err)
+			break
+		}
+		out, err = patch.Apply(payload)
+		if err != nil {
+			err = fmt.Errorf("failed to apply jsonpatch overlay: %w", err)
+		}
+	case triggersv1.CELOverlayKindJSONMerge:
+		out, err = jsonpatch.MergePatch(payload, b)
+		if err != nil {
+			err = fmt.Errorf("failed to apply jsonmerge overlay: %w", err)
+		}
+	case triggersv1.CELOverlayKindSJSON, "":
+		out, err = sjson.SetRawBytes(payload, u.Key, b)
+		if err != nil {
+			err = fmt.Errorf("failed to sjson for key '%s' to '%s': %w", u.Key, b, err)
+		}
+	default:
+		err = fmt.Errorf("unsupported overlay kind %q", u.Kind)
 	}
 
-	prg, err := env.Program(checked)
+	if err != nil {
+		return nil, traceErr(span, err)
+	}
+	return out, nil
+}
+
+// getEnv lazily builds and caches the *cel.Env for this Interceptor. The env
+// only depends on the Interceptor's static configuration (kube client,
+// namespace, SecretRefs allowlist), not on any individual request, so it is
+// built at most once per Interceptor instance.
+func (w *Interceptor) getEnv() (*cel.Env, error) {
+	w.envOnce.Do(func() {
+		w.env, w.envErr = makeCelEnv(w.EventListenerNamespace, w.KubeClientSet, w.CEL)
+	})
+	return w.env, w.envErr
+}
+
+// envFingerprint identifies the shape of the CEL environment (its declared
+// variables and functions) that compiledProgram's cache key is scoped to.
+// Every Interceptor registers the same declarations today, so this is a
+// constant; it should be bumped whenever makeCelEnv's declarations change,
+// so stale cache entries compiled against an older declaration set are never
+// reused.
+const envFingerprint = "triggers-cel-v1"
+
+// compiledProgram parses, type-checks, and plans expr against env, reusing a
+// cached result when available. Parsing and type-checking are the expensive
+// steps and depend only on the expression text and the env's declarations
+// (envFingerprint), so they are shared across requests and across
+// Interceptor instances that expose the same declarations; planning a
+// cel.Program from the cached checked expression is cheap and is always
+// redone against the caller's own env, since it is what binds the
+// interceptor-specific function implementations (e.g. secretRef).
+func compiledProgram(env *cel.Env, expr string, progOpts ...cel.ProgramOption) (cel.Program, error) {
+	checked, err := checkedExprCache.get(env, expr)
+	if err != nil {
+		return nil, err
+	}
+
+	prg, err := env.Program(checked, progOpts...)
 	if err != nil {
 		return nil, fmt.Errorf("expression %#v failed to create a Program: %s", expr, err)
 	}
+	return prg, nil
+}
+
+func evaluate(ctx context.Context, expr string, env *cel.Env, data map[string]interface{}, progOpts ...cel.ProgramOption) (ref.Val, error) {
+	_, span := tracer.Start(ctx, "cel.evaluate", trace.WithAttributes(
+		attribute.String("triggers.cel.expression", traceExpr(expr)),
+	))
+	defer span.End()
+
+	prg, err := compiledProgram(env, expr, progOpts...)
+	if err != nil {
+		return nil, traceErr(span, err)
+	}
 
+	start := time.Now()
 	out, _, err := prg.Eval(data)
+	span.SetAttributes(attribute.Int64("triggers.cel.duration_ns", time.Since(start).Nanoseconds()))
 	if err != nil {
-		return nil, fmt.Errorf("expression %#v failed to evaluate: %s", expr, err)
+		return nil, traceErr(span, fmt.Errorf("expression %#v failed to evaluate: %s", expr, err))
 	}
+	span.SetAttributes(attribute.String("triggers.cel.result_type", out.Type().TypeName()))
 	return out, nil
 }
 
-func makeCelEnv(request *http.Request, ns string, k kubernetes.Interface) (*cel.Env, error) {
+// traceExpr returns expr verbatim if it's short enough to attach to a span,
+// or a sha256 hex digest otherwise, so a long expression can't blow up span
+// size.
+func traceExpr(expr string) string {
+	if len(expr) <= maxTracedExprLen {
+		return expr
+	}
+	sum := sha256.Sum256([]byte(expr))
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// traceErr records err on span as a failure and returns err unchanged, so
+// call sites can both propagate the error and report it in one line.
+func traceErr(span trace.Span, err error) error {
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+	return err
+}
+
+func makeCelEnv(ns string, k kubernetes.Interface, cfg *triggersv1.CELInterceptor) (*cel.Env, error) {
 	mapStrDyn := decls.NewMapType(decls.String, decls.Dyn)
 	return cel.NewEnv(
-		Triggers(request, ns, k),
+		Triggers(ns, k, cfg),
 		celext.Strings(),
 		cel.Declarations(
 			decls.NewVar("body", mapStrDyn),
@@ -199,15 +343,22 @@ func makeCelEnv(request *http.Request, ns string, k kubernetes.Interface) (*cel.
 		))
 }
 
-func makeEvalContext(body []byte, r *http.Request) (map[string]interface{}, error) {
+func makeEvalContext(body []byte, r *http.Request, ns string, k kubernetes.Interface, cfg *triggersv1.CELInterceptor) (map[string]interface{}, error) {
 	var jsonMap map[string]interface{}
 	err := json.Unmarshal(body, &jsonMap)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse the body as JSON: %s", err)
 	}
+
+	var allowedSecrets []triggersv1.SecretRef
+	if cfg != nil {
+		allowedSecrets = cfg.SecretRefs
+	}
+
 	return map[string]interface{}{
 		"body":       jsonMap,
 		"header":     r.Header,
 		"requestURL": r.URL.String(),
+		"secrets":    newLazySecrets(k, ns, allowedSecrets),
 	}, nil
 }
@@ -0,0 +1,130 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cel
+
+import (
+	"context"
+	"testing"
+
+	triggersv1 "github.com/tektoncd/triggers/pkg/apis/triggers/v1alpha1"
+)
+
+func TestApplyOverlay_JSONPatch(t *testing.T) {
+	payload := []byte(`{"owner":"foo","ref":"main"}`)
+
+	tests := []struct {
+		name    string
+		b       []byte
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "valid patch",
+			b:    []byte(`[{"op":"replace","path":"/owner","value":"bar"}]`),
+			want: `{"owner":"bar","ref":"main"}`,
+		},
+		{
+			name:    "malformed patch",
+			b:       []byte(`not a patch`),
+			wantErr: true,
+		},
+		{
+			name:    "patch removes a path that doesn't exist",
+			b:       []byte(`[{"op":"remove","path":"/nonexistent"}]`),
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			u := triggersv1.CELOverlay{Kind: triggersv1.CELOverlayKindJSONPatch}
+			out, err := applyOverlay(context.Background(), payload, u, tc.b)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("applyOverlay() = %s, want error", out)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("applyOverlay() returned unexpected error: %v", err)
+			}
+			if string(out) != tc.want {
+				t.Errorf("applyOverlay() = %s, want %s", out, tc.want)
+			}
+		})
+	}
+}
+
+func TestApplyOverlay_JSONMerge(t *testing.T) {
+	payload := []byte(`{"owner":"foo","nested":{"a":1,"b":2}}`)
+
+	tests := []struct {
+		name    string
+		b       []byte
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "valid merge overwrites a field and leaves others alone",
+			b:    []byte(`{"owner":"bar"}`),
+			want: `{"nested":{"a":1,"b":2},"owner":"bar"}`,
+		},
+		{
+			name: "null removes a key per RFC 7396",
+			b:    []byte(`{"nested":{"a":null}}`),
+			want: `{"nested":{"b":2},"owner":"foo"}`,
+		},
+		{
+			name:    "malformed merge document",
+			b:       []byte(`not json`),
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			u := triggersv1.CELOverlay{Kind: triggersv1.CELOverlayKindJSONMerge}
+			out, err := applyOverlay(context.Background(), payload, u, tc.b)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("applyOverlay() = %s, want error", out)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("applyOverlay() returned unexpected error: %v", err)
+			}
+			if string(out) != tc.want {
+				t.Errorf("applyOverlay() = %s, want %s", out, tc.want)
+			}
+		})
+	}
+}
+
+func TestApplyOverlay_SJSON(t *testing.T) {
+	payload := []byte(`{"owner":"foo"}`)
+
+	u := triggersv1.CELOverlay{Kind: triggersv1.CELOverlayKindSJSON, Key: "truncated_sha"}
+	out, err := applyOverlay(context.Background(), payload, u, []byte(`"abc123"`))
+	if err != nil {
+		t.Fatalf("applyOverlay() returned unexpected error: %v", err)
+	}
+	want := `{"owner":"foo","truncated_sha":"abc123"}`
+	if string(out) != want {
+		t.Errorf("applyOverlay() = %s, want %s", out, want)
+	}
+}
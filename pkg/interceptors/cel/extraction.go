@@ -0,0 +1,124 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cel
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"regexp"
+
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+	"k8s.io/client-go/util/jsonpath"
+)
+
+// jsonpathExtract implements jsonpath(body, path): it evaluates a
+// Kubernetes-style JSONPath expression (e.g. "$.commits[*].id") against
+// body and returns every match as a CEL list, so a filter or overlay can
+// route on, or pull out, values nested in arrays without a chain of
+// index/has() expressions.
+func jsonpathExtract(bodyVal, pathVal ref.Val) ref.Val {
+	path, ok := pathVal.(types.String)
+	if !ok {
+		return types.NewErr("jsonpath: path must be a string, got %v", pathVal.Type())
+	}
+
+	raw, err := bodyVal.ConvertToNative(reflect.TypeOf(map[string]interface{}{}))
+	if err != nil {
+		return types.NewErr("jsonpath: body must be a map: %s", err)
+	}
+
+	jp := jsonpath.New("interceptor")
+	jp.AllowMissingKeys(true)
+	if err := jp.Parse(fmt.Sprintf("{%s}", string(path))); err != nil {
+		return types.NewErr("jsonpath: invalid path %q: %s", path, err)
+	}
+
+	results, err := jp.FindResults(raw)
+	if err != nil {
+		return types.NewErr("jsonpath: %s", err)
+	}
+
+	out := make([]interface{}, 0, len(results))
+	for _, set := range results {
+		for _, v := range set {
+			out = append(out, v.Interface())
+		}
+	}
+
+	return types.DefaultTypeAdapter.NativeToValue(out)
+}
+
+// regexExtract implements regexExtract(str, pattern, groupIdx): it returns
+// capture group groupIdx (0 is the whole match) of the first match of
+// pattern in str, or a CEL error if there is no match or the group doesn't
+// exist.
+func regexExtract(args ...ref.Val) ref.Val {
+	if len(args) != 3 {
+		return types.NewErr("regexExtract: expected 3 arguments, got %d", len(args))
+	}
+	str, ok := args[0].(types.String)
+	if !ok {
+		return types.NewErr("regexExtract: str must be a string, got %v", args[0].Type())
+	}
+	pattern, ok := args[1].(types.String)
+	if !ok {
+		return types.NewErr("regexExtract: pattern must be a string, got %v", args[1].Type())
+	}
+	groupIdx, ok := args[2].(types.Int)
+	if !ok {
+		return types.NewErr("regexExtract: groupIdx must be an int, got %v", args[2].Type())
+	}
+
+	re, err := regexp.Compile(string(pattern))
+	if err != nil {
+		return types.NewErr("regexExtract: invalid pattern %q: %s", pattern, err)
+	}
+
+	m := re.FindStringSubmatch(string(str))
+	if m == nil {
+		return types.NewErr("regexExtract: pattern %q did not match %q", pattern, str)
+	}
+	if int(groupIdx) < 0 || int(groupIdx) >= len(m) {
+		return types.NewErr("regexExtract: pattern %q has no group %d", pattern, groupIdx)
+	}
+
+	return types.String(m[groupIdx])
+}
+
+// parseURL implements parseURL(str): it splits str into a map with
+// scheme/host/path/query keys, so a filter can route on part of a URL (e.g.
+// a ref or a clone URL) without hand-rolled string splitting.
+func parseURL(strVal ref.Val) ref.Val {
+	str, ok := strVal.(types.String)
+	if !ok {
+		return types.NewErr("parseURL: argument must be a string, got %v", strVal.Type())
+	}
+
+	u, err := url.Parse(string(str))
+	if err != nil {
+		return types.NewErr("parseURL: invalid URL %q: %s", str, err)
+	}
+
+	return types.DefaultTypeAdapter.NativeToValue(map[string]string{
+		"scheme": u.Scheme,
+		"host":   u.Host,
+		"path":   u.Path,
+		"query":  u.RawQuery,
+	})
+}
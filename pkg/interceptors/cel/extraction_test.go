@@ -0,0 +1,175 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cel
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+	"github.com/google/cel-go/common/types/traits"
+)
+
+func mustNativeMap(v map[string]interface{}) ref.Val {
+	return types.DefaultTypeAdapter.NativeToValue(v)
+}
+
+func TestJsonpathExtract(t *testing.T) {
+	body := mustNativeMap(map[string]interface{}{
+		"commits": []interface{}{
+			map[string]interface{}{"id": "abc"},
+			map[string]interface{}{"id": "def"},
+		},
+	})
+
+	t.Run("happy path returns every match", func(t *testing.T) {
+		out := jsonpathExtract(body, types.String("$.commits[*].id"))
+		if types.IsError(out) {
+			t.Fatalf("jsonpathExtract() returned unexpected error: %v", out)
+		}
+		lister, ok := out.(traits.Lister)
+		if !ok {
+			t.Fatalf("jsonpathExtract() = %v (%T), want a list", out, out)
+		}
+		size := int(lister.Size().(types.Int))
+		got := make([]string, size)
+		for i := 0; i < size; i++ {
+			s, ok := lister.Get(types.Int(i)).(types.String)
+			if !ok {
+				t.Fatalf("jsonpathExtract() element %d is not a string: %v", i, lister.Get(types.Int(i)))
+			}
+			got[i] = string(s)
+		}
+		want := []string{"abc", "def"}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("jsonpathExtract() = %v, want %v", got, want)
+		}
+	})
+
+	t.Run("invalid path", func(t *testing.T) {
+		out := jsonpathExtract(body, types.String("$.["))
+		if !types.IsError(out) {
+			t.Fatalf("jsonpathExtract() = %v, want error", out)
+		}
+	})
+
+	t.Run("path must be a string", func(t *testing.T) {
+		out := jsonpathExtract(body, types.Int(1))
+		if !types.IsError(out) {
+			t.Fatalf("jsonpathExtract() = %v, want error", out)
+		}
+	})
+}
+
+func TestRegexExtract(t *testing.T) {
+	tests := []struct {
+		name     string
+		str      string
+		pattern  string
+		groupIdx int
+		want     string
+		wantErr  bool
+	}{
+		{
+			name:     "happy path extracts a capture group",
+			str:      "refs/heads/main",
+			pattern:  `refs/heads/(\w+)`,
+			groupIdx: 1,
+			want:     "main",
+		},
+		{
+			name:    "no match",
+			str:     "refs/tags/v1",
+			pattern: `refs/heads/(\w+)`,
+			wantErr: true,
+		},
+		{
+			name:    "invalid pattern",
+			str:     "refs/heads/main",
+			pattern: `(`,
+			wantErr: true,
+		},
+		{
+			name:     "group index out of range",
+			str:      "refs/heads/main",
+			pattern:  `refs/heads/(\w+)`,
+			groupIdx: 5,
+			wantErr:  true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			out := regexExtract(types.String(tc.str), types.String(tc.pattern), types.Int(tc.groupIdx))
+			if tc.wantErr {
+				if !types.IsError(out) {
+					t.Fatalf("regexExtract() = %v, want error", out)
+				}
+				return
+			}
+			s, ok := out.(types.String)
+			if !ok {
+				t.Fatalf("regexExtract() returned non-string %v", out)
+			}
+			if string(s) != tc.want {
+				t.Errorf("regexExtract() = %q, want %q", string(s), tc.want)
+			}
+		})
+	}
+}
+
+func TestParseURL(t *testing.T) {
+	t.Run("happy path splits a URL into its parts", func(t *testing.T) {
+		out := parseURL(types.String("https://example.com/foo/bar?a=b"))
+		if types.IsError(out) {
+			t.Fatalf("parseURL() returned unexpected error: %v", out)
+		}
+		mapper, ok := out.(traits.Mapper)
+		if !ok {
+			t.Fatalf("parseURL() = %v (%T), want a map", out, out)
+		}
+		for key, want := range map[string]string{
+			"scheme": "https",
+			"host":   "example.com",
+			"path":   "/foo/bar",
+			"query":  "a=b",
+		} {
+			val, found := mapper.Find(types.String(key))
+			if !found {
+				t.Fatalf("parseURL() result missing key %q", key)
+			}
+			if got := string(val.(types.String)); got != want {
+				t.Errorf("parseURL()[%q] = %q, want %q", key, got, want)
+			}
+		}
+	})
+
+	t.Run("argument must be a string", func(t *testing.T) {
+		out := parseURL(types.Int(1))
+		if !types.IsError(out) {
+			t.Fatalf("parseURL() = %v, want error", out)
+		}
+	})
+
+	t.Run("invalid URL", func(t *testing.T) {
+		out := parseURL(types.String("http://a b.com/"))
+		if !types.IsError(out) {
+			t.Fatalf("parseURL() = %v, want error", out)
+		}
+	})
+}
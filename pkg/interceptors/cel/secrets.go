@@ -0,0 +1,176 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cel
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+	"github.com/google/cel-go/common/types/traits"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	triggersv1 "github.com/tektoncd/triggers/pkg/apis/triggers/v1alpha1"
+)
+
+// secretDataKey is the Secret data key that signature-verification helpers
+// read the shared token/secret from.
+const secretDataKey = "secretToken"
+
+// fetchSecretValue reads key from the named Secret in ns.
+func fetchSecretValue(k kubernetes.Interface, ns, name, key string) (string, error) {
+	if k == nil {
+		return "", fmt.Errorf("no kube client configured")
+	}
+	secret, err := k.CoreV1().Secrets(ns).Get(context.Background(), name, metav1.GetOptions{})
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch secret %s/%s: %w", ns, name, err)
+	}
+	val, ok := secret.Data[key]
+	if !ok {
+		return "", fmt.Errorf("secret %s/%s has no %q key", ns, name, key)
+	}
+	return string(val), nil
+}
+
+// lazySecrets backs the `secrets` CEL variable. It implements traits.Mapper
+// directly over the EventListener's SecretRefs allowlist instead of eagerly
+// fetching every allowlisted Secret, so a filter that never reads `secrets`
+// never talks to the API server, and an expression that only reads one key
+// out of a large allowlist only fetches that one Secret.
+type lazySecrets struct {
+	kube    kubernetes.Interface
+	ns      string
+	allowed []triggersv1.SecretRef
+}
+
+func newLazySecrets(k kubernetes.Interface, ns string, allowed []triggersv1.SecretRef) *lazySecrets {
+	return &lazySecrets{kube: k, ns: ns, allowed: allowed}
+}
+
+// key is a Secret name, as in `secrets['my-token']`. The value is read from
+// the first allowlist entry with that name: its SecretKey, or secretDataKey
+// when the entry has none. Keys of the form "secretName/key" are not parsed;
+// use secretRef to read a specific key.
+func (s *lazySecrets) Find(key ref.Val) (ref.Val, bool) {
+	name, ok := key.(types.String)
+	if !ok {
+		return nil, false
+	}
+	for _, a := range s.allowed {
+		if a.SecretName != string(name) {
+			continue
+		}
+		dataKey := a.SecretKey
+		if dataKey == "" {
+			dataKey = secretDataKey
+		}
+		val, err := fetchSecretValue(s.kube, s.ns, a.SecretName, dataKey)
+		if err != nil {
+			return nil, false
+		}
+		return types.String(val), true
+	}
+	return nil, false
+}
+
+func (s *lazySecrets) Get(key ref.Val) ref.Val {
+	val, ok := s.Find(key)
+	if !ok {
+		return types.NewErr("no such key: %v", key)
+	}
+	return val
+}
+
+func (s *lazySecrets) Contains(key ref.Val) ref.Val {
+	_, ok := s.Find(key)
+	return types.Bool(ok)
+}
+
+func (s *lazySecrets) Size() ref.Val {
+	return types.Int(len(s.allowed))
+}
+
+func (s *lazySecrets) Iterator() traits.Iterator {
+	names := make([]ref.Val, 0, len(s.allowed))
+	for _, a := range s.allowed {
+		names = append(names, types.String(a.SecretName))
+	}
+	return &secretNameIterator{names: names}
+}
+
+func (s *lazySecrets) ConvertToNative(typeDesc reflect.Type) (interface{}, error) {
+	return nil, fmt.Errorf("secrets cannot be converted to a native type")
+}
+
+func (s *lazySecrets) ConvertToType(typeVal ref.Type) ref.Val {
+	if typeVal == types.MapType {
+		return s
+	}
+	return types.NewErr("type conversion error from 'secrets' to '%s'", typeVal)
+}
+
+func (s *lazySecrets) Equal(other ref.Val) ref.Val {
+	o, ok := other.(*lazySecrets)
+	return types.Bool(ok && o == s)
+}
+
+func (s *lazySecrets) Type() ref.Type {
+	return types.MapType
+}
+
+func (s *lazySecrets) Value() interface{} {
+	return s
+}
+
+type secretNameIterator struct {
+	names []ref.Val
+	idx   int
+}
+
+func (i *secretNameIterator) HasNext() ref.Val {
+	return types.Bool(i.idx < len(i.names))
+}
+
+func (i *secretNameIterator) Next() ref.Val {
+	v := i.names[i.idx]
+	i.idx++
+	return v
+}
+
+func (i *secretNameIterator) ConvertToNative(typeDesc reflect.Type) (interface{}, error) {
+	return nil, fmt.Errorf("secretNameIterator cannot be converted to a native type")
+}
+
+func (i *secretNameIterator) ConvertToType(typeVal ref.Type) ref.Val {
+	return types.NewErr("type conversion error from 'iterator' to '%s'", typeVal)
+}
+
+func (i *secretNameIterator) Equal(other ref.Val) ref.Val {
+	return types.Bool(false)
+}
+
+func (i *secretNameIterator) Type() ref.Type {
+	return types.IteratorType
+}
+
+func (i *secretNameIterator) Value() interface{} {
+	return i
+}
@@ -0,0 +1,68 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cel
+
+import (
+	"testing"
+
+	"github.com/google/cel-go/common/types"
+	fakekubeclientset "k8s.io/client-go/kubernetes/fake"
+
+	triggersv1 "github.com/tektoncd/triggers/pkg/apis/triggers/v1alpha1"
+)
+
+func TestLazySecretsFind(t *testing.T) {
+	kube := fakekubeclientset.NewSimpleClientset(
+		newFakeTokenSecret(t, "allowed-secret", "allowed-value"),
+		newFakeTokenSecret(t, "not-allowlisted-secret", "should-never-surface"),
+	)
+
+	allowed := []triggersv1.SecretRef{
+		{SecretName: "allowed-secret"},
+	}
+	secrets := newLazySecrets(kube, testNamespace, allowed)
+
+	tests := []struct {
+		name    string
+		key     string
+		wantOK  bool
+		wantVal string
+	}{
+		{name: "allowlisted secret is found", key: "allowed-secret", wantOK: true, wantVal: "allowed-value"},
+		{name: "secret not on allowlist is denied, even though it exists in the cluster", key: "not-allowlisted-secret", wantOK: false},
+		{name: "unknown secret name is denied", key: "nonexistent-secret", wantOK: false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			val, ok := secrets.Find(types.String(tc.key))
+			if ok != tc.wantOK {
+				t.Fatalf("Find(%q) ok = %v, want %v", tc.key, ok, tc.wantOK)
+			}
+			if !tc.wantOK {
+				return
+			}
+			s, isString := val.(types.String)
+			if !isString {
+				t.Fatalf("Find(%q) returned non-string value %v", tc.key, val)
+			}
+			if string(s) != tc.wantVal {
+				t.Errorf("Find(%q) = %q, want %q", tc.key, string(s), tc.wantVal)
+			}
+		})
+	}
+}
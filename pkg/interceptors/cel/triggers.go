@@ -0,0 +1,298 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cel
+
+import (
+	"crypto/hmac"
+	"crypto/sha1" // nolint:gosec
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"strings"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/checker/decls"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+	"github.com/google/cel-go/common/types/traits"
+	"github.com/google/cel-go/interpreter/functions"
+	exprpb "google.golang.org/genproto/googleapis/api/expr/v1alpha1"
+	"k8s.io/client-go/kubernetes"
+
+	triggersv1 "github.com/tektoncd/triggers/pkg/apis/triggers/v1alpha1"
+)
+
+// Triggers returns a cel.EnvOption that registers the Tekton-specific CEL
+// extensions: signature verification helpers that compare a webhook's
+// signature header against a token stored in a Kubernetes Secret, and
+// allowlist-gated access to Kubernetes Secrets via the `secrets` variable
+// and `secretRef` function.
+//
+// Triggers only closes over data that is fixed for the lifetime of an
+// Interceptor (the kube client, namespace, and SecretRefs allowlist), not
+// over any particular request, so the *cel.Env it produces can be built once
+// per Interceptor and reused across requests. verifyGitHubSignature and
+// verifyBitbucketSignature are the one exception: they are declared here,
+// but their implementations need the current request's raw body, so they
+// are bound separately by rawBodyFunctions at evaluation time.
+func Triggers(ns string, k kubernetes.Interface, cfg *triggersv1.CELInterceptor) cel.EnvOption {
+	var allowed []triggersv1.SecretRef
+	if cfg != nil {
+		allowed = cfg.SecretRefs
+	}
+	return cel.Lib(&triggersLib{
+		ns:             ns,
+		kube:           k,
+		allowedSecrets: allowed,
+	})
+}
+
+type triggersLib struct {
+	ns             string
+	kube           kubernetes.Interface
+	allowedSecrets []triggersv1.SecretRef
+}
+
+func (t *triggersLib) CompileOptions() []cel.EnvOption {
+	newFn := decls.NewFunction
+	sig2 := func(name string) cel.EnvOption {
+		return cel.Declarations(newFn(name,
+			decls.NewOverload(name, []*exprpb.Type{decls.String, decls.Dyn}, decls.Bool)))
+	}
+	return []cel.EnvOption{
+		sig2("verifyGitHubSignature"),
+		sig2("verifyBitbucketSignature"),
+		cel.Declarations(
+			newFn("verifyGitLabToken",
+				decls.NewOverload("verifyGitLabToken", []*exprpb.Type{decls.String, decls.Dyn}, decls.Bool)),
+			decls.NewVar("secrets", decls.NewMapType(decls.String, decls.String)),
+			newFn("secretRef",
+				decls.NewOverload("secretRef", []*exprpb.Type{decls.String, decls.String}, decls.String)),
+			newFn("jsonpath",
+				decls.NewOverload("jsonpath", []*exprpb.Type{decls.Dyn, decls.String}, decls.NewListType(decls.Dyn))),
+			newFn("regexExtract",
+				decls.NewOverload("regexExtract", []*exprpb.Type{decls.String, decls.String, decls.Int}, decls.String)),
+			newFn("parseURL",
+				decls.NewOverload("parseURL", []*exprpb.Type{decls.String}, decls.NewMapType(decls.String, decls.String))),
+		),
+	}
+}
+
+func (t *triggersLib) ProgramOptions() []cel.ProgramOption {
+	return []cel.ProgramOption{
+		cel.Functions(
+			&functions.Overload{
+				Operator: "verifyGitLabToken",
+				Binary:   t.verifyToken(),
+			},
+			&functions.Overload{
+				Operator: "secretRef",
+				Binary:   t.secretRef(),
+			},
+			&functions.Overload{
+				Operator: "jsonpath",
+				Binary:   jsonpathExtract,
+			},
+			&functions.Overload{
+				Operator: "regexExtract",
+				Function: regexExtract,
+			},
+			&functions.Overload{
+				Operator: "parseURL",
+				Unary:    parseURL,
+			},
+		),
+	}
+}
+
+// secretRef returns a CEL binary function implementing secretRef(name, key):
+// it looks up key in the named Secret, after checking the call against
+// allowedSecrets.
+func (t *triggersLib) secretRef() func(ref.Val, ref.Val) ref.Val {
+	return func(nameVal, keyVal ref.Val) ref.Val {
+		name, ok := nameVal.(types.String)
+		if !ok {
+			return types.NewErr("secretRef: name must be a string, got %v", nameVal.Type())
+		}
+		key, ok := keyVal.(types.String)
+		if !ok {
+			return types.NewErr("secretRef: key must be a string, got %v", keyVal.Type())
+		}
+
+		if !secretAccessAllowed(t.allowedSecrets, string(name), string(key)) {
+			return types.NewErr("secretRef: access to %s/%s is not allowlisted on this interceptor", name, key)
+		}
+
+		val, err := fetchSecretValue(t.kube, t.ns, string(name), string(key))
+		if err != nil {
+			return types.NewErr("secretRef: %w", err)
+		}
+		return types.String(val)
+	}
+}
+
+// secretAccessAllowed reports whether name/key is permitted by the
+// interceptor's SecretRefs allowlist. An empty SecretKey on the allowlist
+// entry permits every key in that Secret.
+func secretAccessAllowed(allowed []triggersv1.SecretRef, name, key string) bool {
+	for _, a := range allowed {
+		if a.SecretName == name && (a.SecretKey == "" || a.SecretKey == key) {
+			return true
+		}
+	}
+	return false
+}
+
+// rawBodyFunctions binds verifyGitHubSignature and verifyBitbucketSignature
+// against rawBody, the current request's raw payload bytes. Unlike the rest
+// of this package's CEL functions, which only depend on data fixed for the
+// lifetime of an Interceptor (and so are wired up once in
+// triggersLib.ProgramOptions), these two need the bytes of the request
+// currently being evaluated. Binding them this way keeps rawBody out of the
+// CEL-facing function signature - callers still write
+// verifyGitHubSignature(secretRef, header) - while letting the *cel.Env
+// itself stay static and shared across requests: env.Program accepts extra
+// cel.ProgramOptions per call, so this is supplied fresh by ExecuteTrigger
+// for every request instead of being baked into the cached env.
+func rawBodyFunctions(k kubernetes.Interface, ns string, rawBody []byte) cel.ProgramOption {
+	return cel.Functions(
+		&functions.Overload{
+			Operator: "verifyGitHubSignature",
+			Binary:   verifyHMAC(k, ns, rawBody),
+		},
+		&functions.Overload{
+			Operator: "verifyBitbucketSignature",
+			Binary:   verifyHMAC(k, ns, rawBody),
+		},
+	)
+}
+
+// sha1Prefix and sha256Prefix are the digest prefixes GitHub (and
+// Bitbucket, which uses the same scheme) put on their signature headers:
+// sha1Prefix on the legacy X-Hub-Signature header, sha256Prefix on
+// X-Hub-Signature-256.
+const (
+	sha1Prefix   = "sha1="
+	sha256Prefix = "sha256="
+)
+
+// verifyHMAC returns a CEL binary function implementing
+// verify*Signature(secretRef, header): it fetches secretDataKey from the
+// Secret named by secretRef, HMACs rawBody with it, and compares the result
+// in constant time against header. The digest algorithm is taken from
+// header's own prefix (sha256=, or the legacy sha1=) rather than fixed per
+// caller, so the same function verifies both X-Hub-Signature-256 and the
+// legacy X-Hub-Signature header.
+func verifyHMAC(k kubernetes.Interface, ns string, rawBody []byte) func(ref.Val, ref.Val) ref.Val {
+	return func(secretRefVal, headerVal ref.Val) ref.Val {
+		secretName, ok := secretRefVal.(types.String)
+		if !ok {
+			return types.NewErr("verifyHMAC: secretRef must be a string, got %v", secretRefVal.Type())
+		}
+
+		header, err := headerStringValue(headerVal)
+		if err != nil {
+			return types.NewErr("verifyHMAC: %w", err)
+		}
+
+		var newHash func() hash.Hash
+		switch {
+		case strings.HasPrefix(header, sha256Prefix):
+			newHash = sha256.New
+			header = strings.TrimPrefix(header, sha256Prefix)
+		case strings.HasPrefix(header, sha1Prefix):
+			newHash = sha1.New //nolint:gosec
+			header = strings.TrimPrefix(header, sha1Prefix)
+		default:
+			return types.NewErr("verifyHMAC: header %q has no recognized sha1= or sha256= prefix", header)
+		}
+
+		token, err := fetchSecretToken(k, ns, string(secretName))
+		if err != nil {
+			return types.NewErr("verifyHMAC: %w", err)
+		}
+
+		mac := hmacSum(newHash, token, rawBody)
+
+		expected, err := hex.DecodeString(header)
+		if err != nil {
+			return types.Bool(false)
+		}
+
+		return types.Bool(subtle.ConstantTimeCompare(mac, expected) == 1)
+	}
+}
+
+// verifyToken returns a CEL binary function that compares a header value
+// directly (in constant time) against secretDataKey from the named Secret,
+// for providers such as GitLab that send a plain shared token rather than an
+// HMAC digest.
+func (t *triggersLib) verifyToken() func(ref.Val, ref.Val) ref.Val {
+	return func(secretRefVal, headerVal ref.Val) ref.Val {
+		secretName, ok := secretRefVal.(types.String)
+		if !ok {
+			return types.NewErr("verifyToken: secretRef must be a string, got %v", secretRefVal.Type())
+		}
+
+		header, err := headerStringValue(headerVal)
+		if err != nil {
+			return types.NewErr("verifyToken: %w", err)
+		}
+
+		token, err := fetchSecretToken(t.kube, t.ns, string(secretName))
+		if err != nil {
+			return types.NewErr("verifyToken: %w", err)
+		}
+
+		return types.Bool(subtle.ConstantTimeCompare([]byte(header), []byte(token)) == 1)
+	}
+}
+
+// headerStringValue normalizes a CEL value for a header lookup: http.Header
+// values are exposed to CEL as a list<string>, but callers may also pass a
+// single string directly.
+func headerStringValue(v ref.Val) (string, error) {
+	switch val := v.(type) {
+	case types.String:
+		return string(val), nil
+	case traits.Lister:
+		if val.Size() == types.IntZero {
+			return "", fmt.Errorf("header value is empty")
+		}
+		first := val.Get(types.IntZero)
+		s, ok := first.(types.String)
+		if !ok {
+			return "", fmt.Errorf("header value must be a string, got %v", first.Type())
+		}
+		return string(s), nil
+	default:
+		return "", fmt.Errorf("header value must be a string or list of strings, got %v", v.Type())
+	}
+}
+
+func hmacSum(newHash func() hash.Hash, key string, data []byte) []byte {
+	mac := hmac.New(newHash, []byte(key))
+	mac.Write(data) // nolint:errcheck
+	return mac.Sum(nil)
+}
+
+// fetchSecretToken reads secretDataKey from the named Secret in ns.
+func fetchSecretToken(k kubernetes.Interface, ns, name string) (string, error) {
+	return fetchSecretValue(k, ns, name, secretDataKey)
+}
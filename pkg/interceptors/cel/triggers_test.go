@@ -0,0 +1,190 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cel
+
+import (
+	"crypto/hmac"
+	"crypto/sha1" // nolint:gosec
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+
+	"github.com/google/cel-go/common/types"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	fakekubeclientset "k8s.io/client-go/kubernetes/fake"
+
+	triggersv1 "github.com/tektoncd/triggers/pkg/apis/triggers/v1alpha1"
+)
+
+const testNamespace = "default"
+
+func newFakeTokenSecret(t *testing.T, name, token string) *corev1.Secret {
+	t.Helper()
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: testNamespace},
+		Data:       map[string][]byte{secretDataKey: []byte(token)},
+	}
+}
+
+func TestVerifyHMAC(t *testing.T) {
+	const token = "s3cr3t"
+	const body = `{"hello":"world"}`
+
+	sha256Sig := func(b string) string {
+		mac := hmac.New(sha256.New, []byte(token))
+		mac.Write([]byte(b)) // nolint:errcheck
+		return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+	}
+	sha1Sig := func(b string) string {
+		mac := hmac.New(sha1.New, []byte(token)) // nolint:gosec
+		mac.Write([]byte(b))                     // nolint:errcheck
+		return "sha1=" + hex.EncodeToString(mac.Sum(nil))
+	}
+
+	tests := []struct {
+		name       string
+		secretName string
+		header     string
+		rawBody    string
+		want       bool
+	}{
+		{
+			name:       "valid signature",
+			secretName: "gh-secret",
+			header:     sha256Sig(body),
+			rawBody:    body,
+			want:       true,
+		},
+		{
+			name:       "wrong signature",
+			secretName: "gh-secret",
+			header:     sha256Sig(body + "tampered"),
+			rawBody:    body,
+			want:       false,
+		},
+		{
+			name:       "wrong secret key",
+			secretName: "does-not-exist",
+			header:     sha256Sig(body),
+			rawBody:    body,
+			want:       false,
+		},
+		{
+			name:       "legacy sha1 prefixed signature is verified",
+			secretName: "gh-secret",
+			header:     sha1Sig(body),
+			rawBody:    body,
+			want:       true,
+		},
+		{
+			name:       "wrong sha1 signature",
+			secretName: "gh-secret",
+			header:     sha1Sig(body + "tampered"),
+			rawBody:    body,
+			want:       false,
+		},
+		{
+			name:       "header with no recognized prefix",
+			secretName: "gh-secret",
+			header:     hex.EncodeToString([]byte("not-a-digest")),
+			rawBody:    body,
+			want:       false,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			kube := fakekubeclientset.NewSimpleClientset(newFakeTokenSecret(t, "gh-secret", token))
+			fn := verifyHMAC(kube, testNamespace, []byte(tc.rawBody))
+
+			out := fn(types.String(tc.secretName), types.String(tc.header))
+			b, ok := out.(types.Bool)
+			if !ok {
+				if tc.want {
+					t.Fatalf("verifyHMAC(%q) returned non-bool %v, want %v", tc.secretName, out, tc.want)
+				}
+				return
+			}
+			if bool(b) != tc.want {
+				t.Errorf("verifyHMAC(%q) = %v, want %v", tc.secretName, bool(b), tc.want)
+			}
+		})
+	}
+}
+
+func TestVerifyToken(t *testing.T) {
+	const token = "gl-token"
+
+	tests := []struct {
+		name       string
+		secretName string
+		header     string
+		want       bool
+	}{
+		{name: "matching token", secretName: "gl-secret", header: token, want: true},
+		{name: "wrong token", secretName: "gl-secret", header: "not-the-token", want: false},
+		{name: "wrong secret key", secretName: "does-not-exist", header: token, want: false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			kube := fakekubeclientset.NewSimpleClientset(newFakeTokenSecret(t, "gl-secret", token))
+			lib := &triggersLib{ns: testNamespace, kube: kube}
+			fn := lib.verifyToken()
+
+			out := fn(types.String(tc.secretName), types.String(tc.header))
+			b, ok := out.(types.Bool)
+			if !ok {
+				if tc.want {
+					t.Fatalf("verifyToken(%q) returned non-bool %v, want %v", tc.secretName, out, tc.want)
+				}
+				return
+			}
+			if bool(b) != tc.want {
+				t.Errorf("verifyToken(%q) = %v, want %v", tc.secretName, bool(b), tc.want)
+			}
+		})
+	}
+}
+
+func TestSecretAccessAllowed(t *testing.T) {
+	allowed := []triggersv1.SecretRef{
+		{SecretName: "scoped-secret", SecretKey: "token"},
+		{SecretName: "wide-open-secret"},
+	}
+
+	tests := []struct {
+		name       string
+		secretName string
+		key        string
+		want       bool
+	}{
+		{name: "allowed name and key", secretName: "scoped-secret", key: "token", want: true},
+		{name: "allowed name, wrong key", secretName: "scoped-secret", key: "other-key", want: false},
+		{name: "any key allowed on wide-open entry", secretName: "wide-open-secret", key: "anything", want: true},
+		{name: "secret not on allowlist at all", secretName: "unlisted-secret", key: "token", want: false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := secretAccessAllowed(allowed, tc.secretName, tc.key); got != tc.want {
+				t.Errorf("secretAccessAllowed(%q, %q) = %v, want %v", tc.secretName, tc.key, got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,86 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package grpc
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	"google.golang.org/grpc"
+
+	"github.com/tektoncd/triggers/pkg/interceptors"
+)
+
+// ClientInterceptor adapts a remote InterceptorService to interceptors.
+// Interceptor, so an EventListener can call an out-of-process, possibly
+// non-Go, interceptor over gRPC exactly like it would call a local one.
+type ClientInterceptor struct {
+	client InterceptorServiceClient
+}
+
+// NewClientInterceptor returns a ClientInterceptor that dials the
+// InterceptorService over cc.
+func NewClientInterceptor(cc grpc.ClientConnInterface) *ClientInterceptor {
+	return &ClientInterceptor{client: NewInterceptorServiceClient(cc)}
+}
+
+// ExecuteTrigger implements interceptors.Interceptor by translating request
+// into an InterceptRequest, calling the remote InterceptorService, and
+// translating the result back into an *http.Response.
+func (c *ClientInterceptor) ExecuteTrigger(request *http.Request) (*http.Response, error) {
+	req, err := toInterceptRequest(request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build intercept request: %w", err)
+	}
+
+	resp, err := c.client.Process(request.Context(), req)
+	if err != nil {
+		return nil, fmt.Errorf("remote interceptor call failed: %w", err)
+	}
+
+	return &http.Response{
+		Header: request.Header,
+		Body:   ioutil.NopCloser(bytes.NewReader(resp.Payload)),
+	}, nil
+}
+
+func toInterceptRequest(request *http.Request) (*InterceptRequest, error) {
+	var payload []byte
+	var err error
+	if request.Body != nil {
+		defer request.Body.Close()
+		payload, err = ioutil.ReadAll(request.Body)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	header := make(map[string]*HeaderValues, len(request.Header))
+	for name, values := range request.Header {
+		header[name] = &HeaderValues{Values: values}
+	}
+
+	return &InterceptRequest{
+		Payload:    payload,
+		Header:     header,
+		RequestURL: request.URL.String(),
+	}, nil
+}
+
+var _ interceptors.Interceptor = (*ClientInterceptor)(nil)
@@ -0,0 +1,84 @@
+// Hand-maintained Go types mirroring proto/interceptor.proto. There is no
+// protoc invocation in this tree yet, so these are not actually generated -
+// keep them in sync with the .proto file by hand until real codegen is
+// wired up.
+
+package grpc
+
+import (
+	proto "github.com/golang/protobuf/proto"
+)
+
+// HeaderValues holds the values of a single HTTP header, mirroring
+// net/http.Header's map[string][]string shape.
+type HeaderValues struct {
+	Values []string `protobuf:"bytes,1,rep,name=values,proto3" json:"values,omitempty"`
+}
+
+func (m *HeaderValues) Reset()         { *m = HeaderValues{} }
+func (m *HeaderValues) String() string { return proto.CompactTextString(m) }
+func (*HeaderValues) ProtoMessage()    {}
+
+func (m *HeaderValues) GetValues() []string {
+	if m != nil {
+		return m.Values
+	}
+	return nil
+}
+
+type InterceptRequest struct {
+	// Payload is the raw, unparsed request body.
+	Payload []byte `protobuf:"bytes,1,opt,name=payload,proto3" json:"payload,omitempty"`
+	// Header holds the incoming request's HTTP headers.
+	Header map[string]*HeaderValues `protobuf:"bytes,2,rep,name=header,proto3" json:"header,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	// RequestURL is the incoming request's URL, as seen by the EventListener.
+	RequestURL string `protobuf:"bytes,3,opt,name=request_url,json=requestUrl,proto3" json:"request_url,omitempty"`
+}
+
+func (m *InterceptRequest) Reset()         { *m = InterceptRequest{} }
+func (m *InterceptRequest) String() string { return proto.CompactTextString(m) }
+func (*InterceptRequest) ProtoMessage()    {}
+
+func (m *InterceptRequest) GetPayload() []byte {
+	if m != nil {
+		return m.Payload
+	}
+	return nil
+}
+
+func (m *InterceptRequest) GetHeader() map[string]*HeaderValues {
+	if m != nil {
+		return m.Header
+	}
+	return nil
+}
+
+func (m *InterceptRequest) GetRequestURL() string {
+	if m != nil {
+		return m.RequestURL
+	}
+	return ""
+}
+
+type InterceptResponse struct {
+	// Payload is the (possibly rewritten) event payload to continue
+	// processing with.
+	Payload []byte `protobuf:"bytes,1,opt,name=payload,proto3" json:"payload,omitempty"`
+}
+
+func (m *InterceptResponse) Reset()         { *m = InterceptResponse{} }
+func (m *InterceptResponse) String() string { return proto.CompactTextString(m) }
+func (*InterceptResponse) ProtoMessage()    {}
+
+func (m *InterceptResponse) GetPayload() []byte {
+	if m != nil {
+		return m.Payload
+	}
+	return nil
+}
+
+func init() {
+	proto.RegisterType((*HeaderValues)(nil), "triggers.interceptors.v1.HeaderValues")
+	proto.RegisterType((*InterceptRequest)(nil), "triggers.interceptors.v1.InterceptRequest")
+	proto.RegisterType((*InterceptResponse)(nil), "triggers.interceptors.v1.InterceptResponse")
+}
@@ -0,0 +1,87 @@
+// Hand-maintained gRPC client/server stubs mirroring proto/interceptor.proto.
+// There is no protoc-gen-go-grpc invocation in this tree yet, so these are
+// not actually generated - keep them in sync with the .proto file by hand
+// until real codegen is wired up.
+
+package grpc
+
+import (
+	context "context"
+
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// InterceptorServiceClient is the client API for InterceptorService.
+type InterceptorServiceClient interface {
+	Process(ctx context.Context, in *InterceptRequest, opts ...grpc.CallOption) (*InterceptResponse, error)
+}
+
+type interceptorServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+// NewInterceptorServiceClient creates a client stub for InterceptorService.
+func NewInterceptorServiceClient(cc grpc.ClientConnInterface) InterceptorServiceClient {
+	return &interceptorServiceClient{cc}
+}
+
+func (c *interceptorServiceClient) Process(ctx context.Context, in *InterceptRequest, opts ...grpc.CallOption) (*InterceptResponse, error) {
+	out := new(InterceptResponse)
+	err := c.cc.Invoke(ctx, "/triggers.interceptors.v1.InterceptorService/Process", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// InterceptorServiceServer is the server API for InterceptorService.
+type InterceptorServiceServer interface {
+	Process(context.Context, *InterceptRequest) (*InterceptResponse, error)
+}
+
+// UnimplementedInterceptorServiceServer may be embedded to have forward
+// compatible implementations.
+type UnimplementedInterceptorServiceServer struct{}
+
+func (UnimplementedInterceptorServiceServer) Process(context.Context, *InterceptRequest) (*InterceptResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Process not implemented")
+}
+
+// RegisterInterceptorServiceServer registers srv with s so that incoming
+// Process calls are dispatched to it.
+func RegisterInterceptorServiceServer(s grpc.ServiceRegistrar, srv InterceptorServiceServer) {
+	s.RegisterService(&_InterceptorService_serviceDesc, srv)
+}
+
+func _InterceptorService_Process_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(InterceptRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InterceptorServiceServer).Process(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/triggers.interceptors.v1.InterceptorService/Process",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InterceptorServiceServer).Process(ctx, req.(*InterceptRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _InterceptorService_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "triggers.interceptors.v1.InterceptorService",
+	HandlerType: (*InterceptorServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Process",
+			Handler:    _InterceptorService_Process_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "proto/interceptor.proto",
+}
@@ -0,0 +1,111 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package grpc lets an interceptors.Interceptor (normally reached over HTTP,
+// in-process) also be served over gRPC, so it can run as a remote or
+// out-of-process sidecar for EventListeners that want typed, streaming-
+// capable transport with deadlines instead of a raw HTTP round trip.
+package grpc
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/tektoncd/triggers/pkg/interceptors"
+)
+
+// Server adapts an interceptors.Interceptor to InterceptorServiceServer.
+// Concurrent Process calls are bounded by maxConcurrent, giving the
+// underlying interceptor backpressure instead of unbounded goroutine growth
+// under load.
+type Server struct {
+	UnimplementedInterceptorServiceServer
+
+	Interceptor interceptors.Interceptor
+
+	sem chan struct{}
+}
+
+// NewServer returns a Server wrapping interceptor. maxConcurrent bounds how
+// many Process calls may run at once; a call beyond that limit blocks until
+// a slot frees up or the call's context is done.
+func NewServer(interceptor interceptors.Interceptor, maxConcurrent int) *Server {
+	return &Server{
+		Interceptor: interceptor,
+		sem:         make(chan struct{}, maxConcurrent),
+	}
+}
+
+// Process implements InterceptorServiceServer by translating req into an
+// *http.Request, running it through the wrapped Interceptor, and translating
+// the result back into an InterceptResponse.
+func (s *Server) Process(ctx context.Context, req *InterceptRequest) (*InterceptResponse, error) {
+	select {
+	case s.sem <- struct{}{}:
+		defer func() { <-s.sem }()
+	case <-ctx.Done():
+		return nil, status.Error(codes.ResourceExhausted, "interceptor is at capacity")
+	}
+
+	httpReq, err := toHTTPRequest(ctx, req)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid intercept request: %s", err)
+	}
+
+	resp, err := s.Interceptor.ExecuteTrigger(httpReq)
+	if err != nil {
+		return nil, status.Errorf(codes.FailedPrecondition, "interceptor rejected request: %s", err)
+	}
+	defer resp.Body.Close()
+
+	payload, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to read interceptor response: %s", err)
+	}
+
+	return &InterceptResponse{Payload: payload}, nil
+}
+
+func toHTTPRequest(ctx context.Context, req *InterceptRequest) (*http.Request, error) {
+	u, err := url.Parse(req.RequestURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid request_url %q: %w", req.RequestURL, err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Body = ioutil.NopCloser(bytes.NewReader(req.Payload))
+
+	for name, values := range req.Header {
+		if values == nil {
+			continue
+		}
+		for _, v := range values.Values {
+			httpReq.Header.Add(name, v)
+		}
+	}
+
+	return httpReq, nil
+}
@@ -0,0 +1,31 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package interceptors defines the contract that EventListener interceptors
+// (CEL, and any out-of-process or language-agnostic interceptor reachable
+// over gRPC) implement.
+package interceptors
+
+import "net/http"
+
+// Interceptor is implemented by anything that can inspect, validate, or
+// rewrite an incoming webhook request before it is bound to a
+// TriggerTemplate. ExecuteTrigger returns a Response whose Body is the
+// (possibly rewritten) event payload; a non-nil error aborts processing of
+// the event.
+type Interceptor interface {
+	ExecuteTrigger(request *http.Request) (*http.Response, error)
+}